@@ -0,0 +1,205 @@
+// Package archive extracts tar, tar.gz, and zip bundles downloaded by
+// bootstrap, so a full bootstrap bundle (binary + config + assets) can
+// ship as a single S3 object.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Format identifies a supported archive encoding.
+type Format int
+
+const (
+	// FormatNone indicates the key isn't a recognized archive.
+	FormatNone Format = iota
+	FormatTar
+	FormatTarGz
+	FormatZip
+)
+
+// DetectFormat returns the archive Format implied by an object key's
+// extension, or FormatNone if it isn't one bootstrap knows how to
+// extract.
+func DetectFormat(key string) Format {
+	switch {
+	case strings.HasSuffix(key, ".tar.gz"), strings.HasSuffix(key, ".tgz"):
+		return FormatTarGz
+	case strings.HasSuffix(key, ".tar"):
+		return FormatTar
+	case strings.HasSuffix(key, ".zip"):
+		return FormatZip
+	default:
+		return FormatNone
+	}
+}
+
+// entrypoints are the names Extract's caller should look for, in
+// order, at the top level of an extracted archive.
+var entrypoints = []string{"bootstrap.sh", "bootstrap.ps1", "manifest.json"}
+
+// FindEntrypoint returns the path to the first recognized entrypoint
+// directly under dir, or "" if none is present.
+func FindEntrypoint(dir string) (string, error) {
+	for _, name := range entrypoints {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !os.IsNotExist(err) {
+			return "", fmt.Errorf("checking for %s: %w", name, err)
+		}
+	}
+	return "", nil
+}
+
+// Extract decompresses the archive at path, in the given format, into
+// destDir, creating it if necessary. Entries whose cleaned path would
+// escape destDir, and symlink entries, are rejected.
+func Extract(path string, format Format, destDir string) error {
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return fmt.Errorf("creating extract directory: %w", err)
+	}
+
+	switch format {
+	case FormatTar, FormatTarGz:
+		return extractTar(path, format, destDir)
+	case FormatZip:
+		return extractZip(path, destDir)
+	default:
+		return fmt.Errorf("unsupported archive format")
+	}
+}
+
+func extractTar(path string, format Format, destDir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if format == FormatTarGz {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("opening gzip stream: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading tar entry: %w", err)
+		}
+
+		target, err := safeJoin(destDir, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(hdr.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := writeFile(target, tr, os.FileMode(hdr.Mode)); err != nil {
+				return fmt.Errorf("writing %s: %w", target, err)
+			}
+		case tar.TypeSymlink, tar.TypeLink:
+			// The link target is attacker-controlled and isn't subject
+			// to the same path cleaning as regular entries; refuse it
+			// rather than try to sanitize it.
+			return fmt.Errorf("refusing to extract link entry %q", hdr.Name)
+		default:
+			// Ignore devices, fifos, and other special entry types.
+		}
+	}
+}
+
+func extractZip(path, destDir string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zr, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		return fmt.Errorf("opening zip archive: %w", err)
+	}
+
+	for _, entry := range zr.File {
+		target, err := safeJoin(destDir, entry.Name)
+		if err != nil {
+			return err
+		}
+
+		if entry.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if entry.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("refusing to extract symlink entry %q", entry.Name)
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			return fmt.Errorf("opening zip entry %s: %w", entry.Name, err)
+		}
+		err = writeFile(target, rc, entry.Mode())
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", target, err)
+		}
+	}
+	return nil
+}
+
+func writeFile(target string, r io.Reader, mode os.FileMode) error {
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, r)
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// safeJoin joins destDir with name, rejecting entries whose cleaned
+// path would escape destDir (the "zip-slip" vulnerability).
+func safeJoin(destDir, name string) (string, error) {
+	cleanDest := filepath.Clean(destDir)
+	target := filepath.Join(cleanDest, name)
+	if target != cleanDest && !strings.HasPrefix(target, cleanDest+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry %q escapes extract directory", name)
+	}
+	return target, nil
+}
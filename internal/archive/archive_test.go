@@ -0,0 +1,150 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		key  string
+		want Format
+	}{
+		{"bundle.tar", FormatTar},
+		{"bundle.tar.gz", FormatTarGz},
+		{"bundle.tgz", FormatTarGz},
+		{"bundle.zip", FormatZip},
+		{"bundle.exe", FormatNone},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.key); got != tt.want {
+			t.Errorf("DetectFormat(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	defer gw.Close()
+	tw := tar.NewWriter(gw)
+	defer tw.Close()
+
+	for name, content := range files {
+		hdr := &tar.Header{Name: name, Size: int64(len(content)), Mode: 0644}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExtractTarGz(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "bundle.tar.gz")
+	writeTarGz(t, archivePath, map[string]string{
+		"bootstrap.sh": "#!/bin/sh\necho hi\n",
+		"assets/a.txt": "hello",
+	})
+
+	destDir := filepath.Join(dir, "out")
+	if err := Extract(archivePath, FormatTarGz, destDir); err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(destDir, "bootstrap.sh"))
+	if err != nil {
+		t.Fatalf("reading extracted file: %v", err)
+	}
+	if string(got) != "#!/bin/sh\necho hi\n" {
+		t.Errorf("unexpected content: %q", got)
+	}
+
+	entry, err := FindEntrypoint(destDir)
+	if err != nil {
+		t.Fatalf("FindEntrypoint failed: %v", err)
+	}
+	if entry != filepath.Join(destDir, "bootstrap.sh") {
+		t.Errorf("FindEntrypoint = %q, want bootstrap.sh", entry)
+	}
+}
+
+func TestExtractTarRejectsZipSlip(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "evil.tar.gz")
+
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	content := "pwned"
+	if err := tw.WriteHeader(&tar.Header{Name: "../../etc/passwd", Size: int64(len(content)), Mode: 0644}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write([]byte(content))
+	tw.Close()
+	gw.Close()
+	f.Close()
+
+	destDir := filepath.Join(dir, "out")
+	if err := Extract(archivePath, FormatTarGz, destDir); err == nil {
+		t.Fatal("expected zip-slip entry to be rejected, got nil error")
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "bundle.zip")
+
+	f, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("manifest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(`{"command":["true"]}`))
+	zw.Close()
+	f.Close()
+
+	destDir := filepath.Join(dir, "out")
+	if err := Extract(archivePath, FormatZip, destDir); err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(destDir, "manifest.json"))
+	if err != nil {
+		t.Fatalf("reading extracted file: %v", err)
+	}
+	if !bytes.Contains(got, []byte("command")) {
+		t.Errorf("unexpected content: %q", got)
+	}
+}
+
+func TestFindEntrypointNone(t *testing.T) {
+	dir := t.TempDir()
+	entry, err := FindEntrypoint(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry != "" {
+		t.Errorf("expected no entrypoint, got %q", entry)
+	}
+}
@@ -0,0 +1,184 @@
+// Package cache implements a local on-disk cache of downloaded
+// objects, keyed by URL and backend ETag, so a fleet re-running the
+// same bootstrap on every boot can skip the download entirely once an
+// object has been fetched once.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"bootstrap/internal/objectstore"
+)
+
+// DefaultMaxSize is the default LRU eviction ceiling: 5 GiB.
+const DefaultMaxSize int64 = 5 << 30
+
+// Dir returns the default cache directory: $XDG_CACHE_HOME/bootstrap
+// on Linux, or the OS equivalent via os.UserCacheDir elsewhere.
+func Dir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving cache directory: %w", err)
+	}
+	return filepath.Join(base, "bootstrap"), nil
+}
+
+// Cache is a directory of previously-downloaded objects keyed by URL
+// and ETag, with LRU eviction to stay under a size budget.
+type Cache struct {
+	dir     string
+	maxSize int64
+}
+
+// New returns a Cache rooted at dir, creating it if necessary. A
+// maxSize of zero or less disables eviction.
+func New(dir string, maxSize int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+	return &Cache{dir: dir, maxSize: maxSize}, nil
+}
+
+// entryPath returns the path a cached copy of objectURL at etag would
+// live at. The URL is hashed so the filename is always filesystem-safe,
+// and the ETag is embedded so a new upload invalidates the old entry
+// rather than colliding with it.
+func (c *Cache) entryPath(objectURL, etag string) string {
+	sum := sha256.Sum256([]byte(objectURL))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+"-"+sanitizeETag(etag))
+}
+
+// sanitizeETag strips surrounding quotes and replaces anything that
+// isn't filename-safe, so backend ETags containing slashes or other
+// awkward characters don't break the cache path.
+func sanitizeETag(etag string) string {
+	etag = strings.Trim(etag, `"`)
+	var b strings.Builder
+	for _, r := range etag {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// Lookup reports the path to a cached copy of objectURL at meta's
+// ETag, if one exists. An object with no ETag can never be cached, so
+// Lookup always misses for it. A hit's modification time is bumped so
+// it survives LRU eviction longer.
+func (c *Cache) Lookup(objectURL string, meta objectstore.ObjectMeta) (path string, ok bool) {
+	if meta.ETag == "" {
+		return "", false
+	}
+	path = c.entryPath(objectURL, meta.ETag)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+	// A size mismatch means the entry is truncated or corrupt (e.g. a
+	// crash partway through a previous Put) despite sharing a name;
+	// treat it as a miss rather than serving bad bytes.
+	if meta.Size > 0 && info.Size() != meta.Size {
+		return "", false
+	}
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return path, true
+}
+
+// Put atomically stores a copy of the already-downloaded file at src
+// into the cache, keyed by objectURL and meta.ETag, then evicts the
+// least-recently-used entries until the cache is back under its size
+// budget. An object with no ETag is not cached.
+func (c *Cache) Put(objectURL string, meta objectstore.ObjectMeta, src string) error {
+	if meta.ETag == "" {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("opening %s to cache it: %w", src, err)
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating cache temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, c.entryPath(objectURL, meta.ETag)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("renaming cache entry into place: %w", err)
+	}
+
+	return c.evict()
+}
+
+// evict removes the least-recently-used (by modification time) cache
+// entries until the directory's total size is back under maxSize.
+func (c *Cache) evict() error {
+	if c.maxSize <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("reading cache directory: %w", err)
+	}
+
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), "tmp-") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, file{filepath.Join(c.dir, e.Name()), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+	if total <= c.maxSize {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	for _, f := range files {
+		if total <= c.maxSize {
+			break
+		}
+		if err := os.Remove(f.path); err != nil {
+			continue
+		}
+		total -= f.size
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bootstrap/internal/objectstore"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+func TestCacheMissThenHit(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	meta := objectstore.ObjectMeta{ETag: "abc123"}
+	if _, ok := c.Lookup("s3://bucket/key", meta); ok {
+		t.Fatal("Lookup hit on an empty cache")
+	}
+
+	src := filepath.Join(t.TempDir(), "downloaded")
+	writeFile(t, src, "hello world")
+
+	if err := c.Put("s3://bucket/key", meta, src); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	path, ok := c.Lookup("s3://bucket/key", meta)
+	if !ok {
+		t.Fatal("Lookup missed after Put")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading cached file: %v", err)
+	}
+	if string(content) != "hello world" {
+		t.Errorf("cached content = %q, want %q", content, "hello world")
+	}
+}
+
+func TestCacheMissesOnETagChange(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	src := filepath.Join(t.TempDir(), "downloaded")
+	writeFile(t, src, "v1")
+	if err := c.Put("s3://bucket/key", objectstore.ObjectMeta{ETag: "v1-etag"}, src); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	if _, ok := c.Lookup("s3://bucket/key", objectstore.ObjectMeta{ETag: "v2-etag"}); ok {
+		t.Fatal("Lookup hit for an object that was re-uploaded with a new ETag")
+	}
+}
+
+func TestCacheSkipsObjectsWithoutETag(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, 0)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	src := filepath.Join(t.TempDir(), "downloaded")
+	writeFile(t, src, "content")
+	if err := c.Put("file:///tmp/x", objectstore.ObjectMeta{}, src); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no cache entries for an object without an ETag, got %d", len(entries))
+	}
+}
+
+func TestEvictionRemovesLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, 20)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	src := filepath.Join(t.TempDir(), "downloaded")
+
+	writeFile(t, src, "0123456789") // 10 bytes
+	if err := c.Put("s3://bucket/old", objectstore.ObjectMeta{ETag: "old"}, src); err != nil {
+		t.Fatalf("Put old: %v", err)
+	}
+	oldPath, _ := c.Lookup("s3://bucket/old", objectstore.ObjectMeta{ETag: "old"})
+	os.Chtimes(oldPath, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour))
+
+	writeFile(t, src, "abcdefghij") // 10 bytes
+	if err := c.Put("s3://bucket/new", objectstore.ObjectMeta{ETag: "new"}, src); err != nil {
+		t.Fatalf("Put new: %v", err)
+	}
+
+	writeFile(t, src, "ZYXWVUTSRQ") // 10 bytes, pushes total to 30 > 20
+	if err := c.Put("s3://bucket/newer", objectstore.ObjectMeta{ETag: "newer"}, src); err != nil {
+		t.Fatalf("Put newer: %v", err)
+	}
+
+	if _, ok := c.Lookup("s3://bucket/old", objectstore.ObjectMeta{ETag: "old"}); ok {
+		t.Error("oldest entry should have been evicted")
+	}
+	if _, ok := c.Lookup("s3://bucket/new", objectstore.ObjectMeta{ETag: "new"}); !ok {
+		t.Error("newer entry should still be cached")
+	}
+	if _, ok := c.Lookup("s3://bucket/newer", objectstore.ObjectMeta{ETag: "newer"}); !ok {
+		t.Error("newest entry should still be cached")
+	}
+}
@@ -0,0 +1,179 @@
+// Package azurestore implements bootstrap's objectstore.Fetcher for
+// az:// URLs and https://<account>.blob.core.windows.net/... URLs
+// backed by Azure Blob Storage.
+package azurestore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"bootstrap/internal/objectstore"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+)
+
+// Backend implements objectstore.Fetcher and objectstore.SidecarFetcher
+// for az:// and *.blob.core.windows.net URLs. The storage account isn't
+// known until a URL is parsed, so the per-account client is built
+// lazily on first use and cached.
+type Backend struct {
+	cred azcore.TokenCredential
+
+	account string
+	client  *azblob.Client
+}
+
+// New creates an Azure backend, loading credentials from the
+// environment/managed identity the usual SDK way.
+func New(ctx context.Context) (*Backend, error) {
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("loading Azure credentials: %w", err)
+	}
+	return &Backend{cred: cred}, nil
+}
+
+func parseURL(objectURL string) (account, container, blob string, err error) {
+	u, err := url.Parse(objectURL)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid Azure blob URL: %w", err)
+	}
+
+	switch {
+	case u.Scheme == "az":
+		account = os.Getenv("AZURE_STORAGE_ACCOUNT")
+		if account == "" {
+			return "", "", "", fmt.Errorf("az:// URLs require AZURE_STORAGE_ACCOUNT to be set")
+		}
+		blob = strings.TrimPrefix(u.Path, "/")
+		if u.Host == "" || blob == "" {
+			return "", "", "", fmt.Errorf("invalid az:// URL (want az://container/blob)")
+		}
+		return account, u.Host, blob, nil
+
+	case strings.HasSuffix(u.Host, ".blob.core.windows.net"):
+		account = strings.TrimSuffix(u.Host, ".blob.core.windows.net")
+		parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)
+		if len(parts) != 2 {
+			return "", "", "", fmt.Errorf("invalid blob URL (want https://<account>.blob.core.windows.net/<container>/<blob>)")
+		}
+		return account, parts[0], parts[1], nil
+
+	default:
+		return "", "", "", fmt.Errorf("not an Azure blob URL (should start with az:// or https://<account>.blob.core.windows.net/)")
+	}
+}
+
+// clientFor returns the azblob.Client for account, creating and caching
+// it on first use. Bootstrap only ever targets one account per run.
+func (b *Backend) clientFor(account string) (*azblob.Client, error) {
+	if b.client != nil && b.account == account {
+		return b.client, nil
+	}
+
+	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", account)
+	client, err := azblob.NewClient(serviceURL, b.cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating Azure client for %s: %w", account, err)
+	}
+	b.account, b.client = account, client
+	return client, nil
+}
+
+// Fetch downloads the whole blob as a single stream.
+func (b *Backend) Fetch(ctx context.Context, objectURL string) (io.ReadCloser, objectstore.ObjectMeta, error) {
+	account, container, blob, err := parseURL(objectURL)
+	if err != nil {
+		return nil, objectstore.ObjectMeta{}, err
+	}
+
+	client, err := b.clientFor(account)
+	if err != nil {
+		return nil, objectstore.ObjectMeta{}, err
+	}
+
+	resp, err := client.DownloadStream(ctx, container, blob, nil)
+	if err != nil {
+		return nil, objectstore.ObjectMeta{}, fmt.Errorf("error getting blob from Azure: %w", err)
+	}
+	return resp.Body, metaFromDownload(resp), nil
+}
+
+// Stat fetches the blob's properties without downloading its content,
+// used by the cache to check for a hit before a full Fetch.
+func (b *Backend) Stat(ctx context.Context, objectURL string) (objectstore.ObjectMeta, error) {
+	account, container, blob, err := parseURL(objectURL)
+	if err != nil {
+		return objectstore.ObjectMeta{}, err
+	}
+
+	client, err := b.clientFor(account)
+	if err != nil {
+		return objectstore.ObjectMeta{}, err
+	}
+
+	props, err := client.ServiceClient().NewContainerClient(container).NewBlobClient(blob).GetProperties(ctx, nil)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return objectstore.ObjectMeta{}, fmt.Errorf("blob %s not found", blob)
+		}
+		return objectstore.ObjectMeta{}, fmt.Errorf("error getting blob metadata from Azure: %w", err)
+	}
+
+	meta := objectstore.ObjectMeta{}
+	if props.ETag != nil {
+		meta.ETag = strings.Trim(string(*props.ETag), `"`)
+	}
+	if props.ContentLength != nil {
+		meta.Size = *props.ContentLength
+	}
+	return meta, nil
+}
+
+// FetchSidecar downloads a blob that may or may not exist, such as a
+// "<blob>.sha256" or "<blob>.minisig" sidecar. It returns
+// (nil, false, nil) if the blob is missing, rather than an error.
+func (b *Backend) FetchSidecar(ctx context.Context, objectURL string) ([]byte, bool, error) {
+	account, container, blob, err := parseURL(objectURL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	client, err := b.clientFor(account)
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := client.DownloadStream(ctx, container, blob, nil)
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("error getting blob from Azure: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("reading %s: %w", blob, err)
+	}
+	return data, true, nil
+}
+
+func metaFromDownload(resp azblob.DownloadStreamResponse) objectstore.ObjectMeta {
+	meta := objectstore.ObjectMeta{}
+	if resp.ETag != nil {
+		meta.ETag = strings.Trim(string(*resp.ETag), `"`)
+	}
+	if resp.ContentLength != nil {
+		meta.Size = *resp.ContentLength
+	}
+	return meta
+}
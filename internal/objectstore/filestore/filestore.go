@@ -0,0 +1,97 @@
+// Package filestore implements bootstrap's objectstore.Fetcher for
+// file:// URLs, letting bootstrap read a bundle staged on local disk or
+// an already-mounted network share the same way it reads from a cloud
+// object store.
+package filestore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"bootstrap/internal/objectstore"
+)
+
+// statETag synthesizes an ETag-like fingerprint for a local file, which
+// has no ETag of its own, from its size and modification time. This is
+// enough for the cache to detect that a file changed since it was last
+// read.
+func statETag(info os.FileInfo) string {
+	return fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// Backend implements objectstore.Fetcher and objectstore.SidecarFetcher
+// for file:// URLs. It has no credentials to load, so New never fails.
+type Backend struct{}
+
+// New creates a local-file backend.
+func New() *Backend {
+	return &Backend{}
+}
+
+func parsePath(objectURL string) (string, error) {
+	u, err := url.Parse(objectURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid file URL: %w", err)
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("not a file URL (should start with file://)")
+	}
+	return u.Path, nil
+}
+
+// Fetch opens the local file named by objectURL.
+func (b *Backend) Fetch(ctx context.Context, objectURL string) (io.ReadCloser, objectstore.ObjectMeta, error) {
+	path, err := parsePath(objectURL)
+	if err != nil {
+		return nil, objectstore.ObjectMeta{}, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, objectstore.ObjectMeta{}, fmt.Errorf("opening %s: %w", path, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, objectstore.ObjectMeta{}, fmt.Errorf("statting %s: %w", path, err)
+	}
+
+	return f, objectstore.ObjectMeta{ETag: statETag(info), Size: info.Size()}, nil
+}
+
+// Stat reports the local file's size and a synthetic ETag, without
+// opening it.
+func (b *Backend) Stat(ctx context.Context, objectURL string) (objectstore.ObjectMeta, error) {
+	path, err := parsePath(objectURL)
+	if err != nil {
+		return objectstore.ObjectMeta{}, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return objectstore.ObjectMeta{}, fmt.Errorf("statting %s: %w", path, err)
+	}
+	return objectstore.ObjectMeta{ETag: statETag(info), Size: info.Size()}, nil
+}
+
+// FetchSidecar reads a "<path>.sha256" or "<path>.minisig" file next to
+// the object, returning (nil, false, nil) if it doesn't exist.
+func (b *Backend) FetchSidecar(ctx context.Context, objectURL string) ([]byte, bool, error) {
+	path, err := parsePath(objectURL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return data, true, nil
+}
@@ -0,0 +1,111 @@
+// Package gcsstore implements bootstrap's objectstore.Fetcher for
+// gs:// URLs backed by Google Cloud Storage.
+package gcsstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"bootstrap/internal/objectstore"
+
+	"cloud.google.com/go/storage"
+)
+
+// Backend implements objectstore.Fetcher and objectstore.SidecarFetcher
+// for gs:// URLs.
+type Backend struct {
+	client *storage.Client
+}
+
+// New creates a GCS backend, loading Application Default Credentials
+// the usual SDK way.
+func New(ctx context.Context) (*Backend, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("creating GCS client: %w", err)
+	}
+	return &Backend{client: client}, nil
+}
+
+func parseURL(objectURL string) (bucket, object string, err error) {
+	u, err := url.Parse(objectURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid GCS URL: %w", err)
+	}
+	if u.Scheme != "gs" {
+		return "", "", fmt.Errorf("not a GCS URL (should start with gs://)")
+	}
+	return u.Host, strings.TrimPrefix(u.Path, "/"), nil
+}
+
+// Fetch downloads the whole object as a single stream.
+func (b *Backend) Fetch(ctx context.Context, objectURL string) (io.ReadCloser, objectstore.ObjectMeta, error) {
+	bucket, object, err := parseURL(objectURL)
+	if err != nil {
+		return nil, objectstore.ObjectMeta{}, err
+	}
+
+	obj := b.client.Bucket(bucket).Object(object)
+	r, err := obj.NewReader(ctx)
+	if err != nil {
+		return nil, objectstore.ObjectMeta{}, fmt.Errorf("error getting object from GCS: %w", err)
+	}
+	return r, metaFromReader(r), nil
+}
+
+// Stat fetches the object's attributes without downloading its
+// content, used by the cache to check for a hit before a full Fetch.
+func (b *Backend) Stat(ctx context.Context, objectURL string) (objectstore.ObjectMeta, error) {
+	bucket, object, err := parseURL(objectURL)
+	if err != nil {
+		return objectstore.ObjectMeta{}, err
+	}
+
+	attrs, err := b.client.Bucket(bucket).Object(object).Attrs(ctx)
+	if err != nil {
+		return objectstore.ObjectMeta{}, fmt.Errorf("error getting object metadata from GCS: %w", err)
+	}
+	return objectstore.ObjectMeta{
+		ETag: strings.Trim(attrs.Etag, `"`),
+		Size: attrs.Size,
+	}, nil
+}
+
+// FetchSidecar downloads an object that may or may not exist, such as a
+// "<object>.sha256" or "<object>.minisig" sidecar. It returns
+// (nil, false, nil) if the object is missing, rather than an error.
+func (b *Backend) FetchSidecar(ctx context.Context, objectURL string) ([]byte, bool, error) {
+	bucket, object, err := parseURL(objectURL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	r, err := b.client.Bucket(bucket).Object(object).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("error getting object from GCS: %w", err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, false, fmt.Errorf("reading %s: %w", object, err)
+	}
+	return data, true, nil
+}
+
+// metaFromReader builds an ObjectMeta from the attributes a
+// storage.Reader exposes mid-stream. Unlike the full ObjectAttrs Stat
+// returns, ReaderObjectAttrs carries no ETag, so callers that need one
+// (the cache) should Stat rather than rely on a Fetch's metadata.
+func metaFromReader(r *storage.Reader) objectstore.ObjectMeta {
+	return objectstore.ObjectMeta{
+		Size: r.Attrs.Size,
+	}
+}
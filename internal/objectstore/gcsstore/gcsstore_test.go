@@ -0,0 +1,51 @@
+package gcsstore
+
+import (
+	"testing"
+
+	"cloud.google.com/go/storage"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		wantBucket string
+		wantObject string
+		wantErr    bool
+	}{
+		{name: "valid", url: "gs://bucket/path/to/bundle.tar.gz", wantBucket: "bucket", wantObject: "path/to/bundle.tar.gz"},
+		{name: "wrong scheme", url: "s3://bucket/key", wantErr: true},
+		{name: "invalid URL", url: "://bad", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, object, err := parseURL(tt.url)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseURL(%q) expected error, got none", tt.url)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseURL(%q) unexpected error: %v", tt.url, err)
+			}
+			if bucket != tt.wantBucket || object != tt.wantObject {
+				t.Errorf("parseURL(%q) = (%q, %q), want (%q, %q)", tt.url, bucket, object, tt.wantBucket, tt.wantObject)
+			}
+		})
+	}
+}
+
+func TestMetaFromReader(t *testing.T) {
+	r := &storage.Reader{Attrs: storage.ReaderObjectAttrs{Size: 1024}}
+
+	meta := metaFromReader(r)
+	if meta.Size != 1024 {
+		t.Errorf("Size = %d, want %d", meta.Size, 1024)
+	}
+	if meta.ETag != "" {
+		t.Errorf("ETag = %q, want empty: ReaderObjectAttrs carries no ETag", meta.ETag)
+	}
+}
@@ -0,0 +1,110 @@
+// Package httpstore implements bootstrap's objectstore.Fetcher for
+// plain https:// and http:// URLs, such as an on-prem mirror or static
+// file server that doesn't speak any cloud object-store API.
+package httpstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"bootstrap/internal/objectstore"
+)
+
+// Backend implements objectstore.Fetcher and objectstore.SidecarFetcher
+// for http:// and https:// URLs using a plain GET request. It has no
+// credentials to load, so New never fails.
+type Backend struct {
+	client *http.Client
+}
+
+// New creates an HTTP backend using http.DefaultClient.
+func New() *Backend {
+	return &Backend{client: http.DefaultClient}
+}
+
+// Fetch downloads objectURL with a single GET request.
+func (b *Backend) Fetch(ctx context.Context, objectURL string) (io.ReadCloser, objectstore.ObjectMeta, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, objectURL, nil)
+	if err != nil {
+		return nil, objectstore.ObjectMeta{}, fmt.Errorf("building request for %s: %w", objectURL, err)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, objectstore.ObjectMeta{}, fmt.Errorf("fetching %s: %w", objectURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, objectstore.ObjectMeta{}, fmt.Errorf("fetching %s: unexpected status %s", objectURL, resp.Status)
+	}
+	return resp.Body, metaFromResponse(resp), nil
+}
+
+// Stat issues a HEAD request to report the object's metadata without
+// downloading its content.
+func (b *Backend) Stat(ctx context.Context, objectURL string) (objectstore.ObjectMeta, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, objectURL, nil)
+	if err != nil {
+		return objectstore.ObjectMeta{}, fmt.Errorf("building request for %s: %w", objectURL, err)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return objectstore.ObjectMeta{}, fmt.Errorf("fetching %s: %w", objectURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return objectstore.ObjectMeta{}, fmt.Errorf("fetching %s: unexpected status %s", objectURL, resp.Status)
+	}
+	return metaFromResponse(resp), nil
+}
+
+// FetchSidecar downloads "<objectURL>.sha256" or "<objectURL>.minisig",
+// returning (nil, false, nil) if the server responds 404.
+func (b *Backend) FetchSidecar(ctx context.Context, objectURL string) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, objectURL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("building request for %s: %w", objectURL, err)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("fetching %s: %w", objectURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("fetching %s: unexpected status %s", objectURL, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("reading %s: %w", objectURL, err)
+	}
+	return data, true, nil
+}
+
+func metaFromResponse(resp *http.Response) objectstore.ObjectMeta {
+	meta := objectstore.ObjectMeta{Size: resp.ContentLength}
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		meta.ETag = strings.Trim(etag, `"`)
+	}
+	// Some static file servers and CDNs surface an upload-time SHA-256
+	// through a custom header; honor it when present.
+	if sum := resp.Header.Get("X-Checksum-Sha256"); sum != "" {
+		meta.ChecksumSHA256 = sum
+	}
+	if meta.Size < 0 {
+		if cl, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+			meta.Size = cl
+		}
+	}
+	return meta
+}
@@ -0,0 +1,106 @@
+// Package objectstore declares the shared types a backend (s3store,
+// gcsstore, azurestore, httpstore, filestore, ...) implements to fetch
+// an object, so the same bootstrap binary works across AWS, GCP,
+// Azure, and on-prem HTTP or file mirrors. It deliberately has no
+// dependency on any backend package; main wires a URL's scheme to the
+// right backend's own constructor, keeping this package a leaf that
+// every backend (and the cache package) can import without a cycle.
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"time"
+)
+
+// Defaults for DownloadOptions, tuned for multi-GB bootstrap bundles.
+// Backends without ranged downloads ignore these.
+const (
+	DefaultConcurrency  = 4
+	DefaultPartSize     = 8 << 20 // 8 MiB
+	DefaultMaxRetries   = 5
+	DefaultStallTimeout = 30 * time.Second
+)
+
+// ObjectMeta carries the metadata a backend can report about an
+// object, used for integrity verification and progress reporting.
+type ObjectMeta struct {
+	// ETag is a backend-specific content fingerprint (S3's, GCS's or
+	// Azure's ETag, ...), with any surrounding quotes stripped.
+	ETag string
+
+	// ChecksumSHA256 is a base64-encoded SHA-256 checksum the backend
+	// stored for the object at upload time, if any.
+	ChecksumSHA256 string
+
+	// Size is the object's content length in bytes.
+	Size int64
+}
+
+// Fetcher downloads a single object and returns its content alongside
+// whatever metadata the backend can report.
+type Fetcher interface {
+	Fetch(ctx context.Context, objectURL string) (io.ReadCloser, ObjectMeta, error)
+}
+
+// SidecarFetcher is implemented by backends that can fetch an optional
+// neighbouring object (a "<url>.sha256" or "<url>.minisig") without
+// erroring when it doesn't exist.
+type SidecarFetcher interface {
+	FetchSidecar(ctx context.Context, objectURL string) ([]byte, bool, error)
+}
+
+// DownloadOptions configures a RangedFetcher's chunking, retry, and
+// progress reporting behavior. A zero Concurrency, PartSize or
+// StallTimeout falls back to the package defaults; a zero MaxRetries
+// means a failed chunk is not retried.
+type DownloadOptions struct {
+	Concurrency  int
+	PartSize     int64
+	MaxRetries   int
+	StallTimeout time.Duration
+	Progress     func(downloaded, total int64)
+}
+
+// RangedFetcher is implemented by backends that support concurrent,
+// retried, ranged downloads into a pre-sized destination. Currently
+// only the S3 backend does; other backends are used through Fetch
+// alone.
+type RangedFetcher interface {
+	Fetcher
+	FetchRanged(ctx context.Context, objectURL string, dst io.WriterAt, opts DownloadOptions) (ObjectMeta, error)
+}
+
+// StatFetcher is implemented by backends that can report an object's
+// metadata with a single cheap request (S3/GCS/Azure HEAD, an HTTP
+// HEAD, a local stat) instead of downloading its content. The cache
+// package uses it to check for a hit before paying for a full Fetch.
+type StatFetcher interface {
+	Stat(ctx context.Context, objectURL string) (ObjectMeta, error)
+}
+
+// ParseURL parses objectURL and reports the *url.URL a caller (main's
+// backend dispatch) switches on. It used to be s3client's parseS3URL,
+// which only had to split an s3:// URL into bucket and key; now that
+// bootstrap supports several backends, each backend's own parseURL
+// does that split and this just validates the URL is well-formed.
+func ParseURL(objectURL string) (*url.URL, error) {
+	u, err := url.Parse(objectURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object URL: %w", err)
+	}
+	return u, nil
+}
+
+// KeyName returns the last path segment of an object URL, used to name
+// a temporary download destination.
+func KeyName(objectURL string) string {
+	u, err := url.Parse(objectURL)
+	if err != nil {
+		return objectURL
+	}
+	return path.Base(u.Path)
+}
@@ -0,0 +1,23 @@
+package objectstore
+
+import (
+	"testing"
+)
+
+func TestKeyName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"s3://bucket/path/to/bundle.tar.gz", "bundle.tar.gz"},
+		{"https://example.com/bundle.zip", "bundle.zip"},
+		{"file:///tmp/bundle", "bundle"},
+		{"not a url", "not a url"},
+	}
+
+	for _, tt := range tests {
+		if got := KeyName(tt.url); got != tt.want {
+			t.Errorf("KeyName(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
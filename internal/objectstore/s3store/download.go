@@ -0,0 +1,219 @@
+package s3store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"bootstrap/internal/objectstore"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"golang.org/x/sync/errgroup"
+)
+
+// download concurrently fetches an object from S3 into dst using
+// ranged GetObject requests, retrying individual chunks with
+// exponential backoff on transient errors, and returns the object's
+// metadata.
+func download(ctx context.Context, api clientAPI, bucket, key string, dst io.WriterAt, opts objectstore.DownloadOptions) (objectstore.ObjectMeta, error) {
+	head, err := api.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return objectstore.ObjectMeta{}, fmt.Errorf("error getting object metadata from S3: %w", err)
+	}
+	meta := metaFromHeadObject(head)
+
+	if meta.Size == 0 {
+		return meta, nil
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = objectstore.DefaultConcurrency
+	}
+	partSize := opts.PartSize
+	if partSize < 1 {
+		partSize = objectstore.DefaultPartSize
+	}
+	if partSize > meta.Size {
+		partSize = meta.Size
+	}
+	stallTimeout := opts.StallTimeout
+	if stallTimeout <= 0 {
+		stallTimeout = objectstore.DefaultStallTimeout
+	}
+
+	numParts := int((meta.Size + partSize - 1) / partSize)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+
+	var (
+		mu         sync.Mutex
+		downloaded int64
+	)
+
+	for i := 0; i < numParts; i++ {
+		start := int64(i) * partSize
+		end := start + partSize - 1
+		if end >= meta.Size {
+			end = meta.Size - 1
+		}
+
+		g.Go(func() error {
+			n, err := downloadPart(gctx, api, bucket, key, start, end, dst, opts.MaxRetries, stallTimeout)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			downloaded += n
+			d := downloaded
+			mu.Unlock()
+			if opts.Progress != nil {
+				opts.Progress(d, meta.Size)
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return objectstore.ObjectMeta{}, err
+	}
+	return meta, nil
+}
+
+// backoffDuration computes the exponential backoff delay before retry
+// attempt, as a variable so tests can shrink it.
+var backoffDuration = func(attempt int) time.Duration {
+	return time.Duration(math.Pow(2, float64(attempt-1))) * time.Second
+}
+
+// downloadPart fetches bytes [start, end] of key, retrying with
+// exponential backoff on retryable errors.
+func downloadPart(ctx context.Context, api clientAPI, bucket, key string, start, end int64, dst io.WriterAt, maxRetries int, stallTimeout time.Duration) (int64, error) {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoffDuration(attempt)):
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}
+
+		n, err := fetchRange(ctx, api, bucket, key, start, end, dst, stallTimeout)
+		if err == nil {
+			return n, nil
+		}
+		lastErr = err
+		if !isRetryable(err) {
+			return 0, fmt.Errorf("chunk %d-%d: %w", start, end, err)
+		}
+	}
+	return 0, fmt.Errorf("chunk %d-%d failed after %d attempts: %w", start, end, maxRetries+1, lastErr)
+}
+
+// errStalled is returned by fetchRange when stallTimeout elapses
+// without any bytes arriving, as opposed to the caller's ctx being
+// cancelled for an unrelated reason.
+var errStalled = errors.New("chunk stalled: no data received within timeout")
+
+// fetchRange issues a single ranged GetObject and copies its body into
+// dst. stallTimeout bounds inactivity, not the whole chunk: the timer
+// is reset on every successful read, so a slow-but-steady connection
+// keeps going indefinitely while a genuinely stuck one is cancelled.
+func fetchRange(ctx context.Context, api clientAPI, bucket, key string, start, end int64, dst io.WriterAt, stallTimeout time.Duration) (int64, error) {
+	stallCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	timer := time.AfterFunc(stallTimeout, cancel)
+	defer timer.Stop()
+
+	rangeHeader := fmt.Sprintf("bytes=%d-%d", start, end)
+	result, err := api.GetObject(stallCtx, &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+		Range:  &rangeHeader,
+	})
+	if err != nil {
+		return 0, stalledOrErr(ctx, stallCtx, err)
+	}
+	defer result.Body.Close()
+
+	body := &stallResetReader{r: result.Body, timer: timer, timeout: stallTimeout}
+	n, err := io.Copy(&offsetWriter{w: dst, off: start}, body)
+	if err != nil {
+		return n, stalledOrErr(ctx, stallCtx, err)
+	}
+	return n, nil
+}
+
+// stalledOrErr reports err as errStalled when it was caused by
+// stallCtx's own cancellation (the stall timer firing) rather than by
+// the caller's ctx, so isRetryable and retry logging see a stable
+// sentinel instead of a bare context.Canceled.
+func stalledOrErr(ctx, stallCtx context.Context, err error) error {
+	if ctx.Err() == nil && stallCtx.Err() != nil {
+		return errStalled
+	}
+	return err
+}
+
+// stallResetReader resets timer to timeout on every read that returns
+// data, turning a one-shot deadline into a true inactivity timeout.
+type stallResetReader struct {
+	r       io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (s *stallResetReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if n > 0 {
+		s.timer.Reset(s.timeout)
+	}
+	return n, err
+}
+
+// offsetWriter adapts an io.WriterAt to io.Writer, writing sequential
+// calls at increasing offsets starting from off.
+type offsetWriter struct {
+	w   io.WriterAt
+	off int64
+}
+
+func (o *offsetWriter) Write(p []byte) (int, error) {
+	n, err := o.w.WriteAt(p, o.off)
+	o.off += int64(n)
+	return n, err
+}
+
+// isRetryable reports whether err represents a transient condition
+// worth retrying: S3 errors known to be transient, or a stall from our
+// own per-chunk inactivity timeout.
+func isRetryable(err error) bool {
+	if errors.Is(err, errStalled) {
+		return true
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "RequestTimeout", "SlowDown", "InternalError", "ServiceUnavailable", "RequestTimeTooSkewed":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,298 @@
+package s3store
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bootstrap/internal/objectstore"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+func init() {
+	// Keep retry tests fast.
+	backoffDuration = func(attempt int) time.Duration { return time.Millisecond }
+}
+
+// mockClientAPI serves HeadObject/GetObject from an in-memory buffer,
+// optionally failing the first N requests for a given range to
+// exercise retry logic.
+type mockClientAPI struct {
+	data []byte
+	etag string
+
+	mu         sync.Mutex
+	failCounts map[string]int
+}
+
+func (m *mockClientAPI) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	size := int64(len(m.data))
+	etag := m.etag
+	return &s3.HeadObjectOutput{ContentLength: &size, ETag: &etag}, nil
+}
+
+func (m *mockClientAPI) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	var rangeHeader string
+	if params.Range != nil {
+		rangeHeader = *params.Range
+	}
+
+	m.mu.Lock()
+	if m.failCounts[rangeHeader] > 0 {
+		m.failCounts[rangeHeader]--
+		m.mu.Unlock()
+		return nil, &smithy.GenericAPIError{Code: "InternalError", Message: "injected failure"}
+	}
+	m.mu.Unlock()
+
+	start, end, err := parseByteRange(rangeHeader, int64(len(m.data)))
+	if err != nil {
+		return nil, err
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(m.data[start : end+1]))}, nil
+}
+
+func parseByteRange(header string, size int64) (int64, int64, error) {
+	if header == "" {
+		return 0, size - 1, nil
+	}
+	header = strings.TrimPrefix(header, "bytes=")
+	parts := strings.SplitN(header, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("bad range %q", header)
+	}
+	start, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad range %q: %w", header, err)
+	}
+	end, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("bad range %q: %w", header, err)
+	}
+	return start, end, nil
+}
+
+// writerAtBuffer is an in-memory io.WriterAt used as the download
+// destination in tests.
+type writerAtBuffer struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (b *writerAtBuffer) WriteAt(p []byte, off int64) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	end := off + int64(len(p))
+	if int64(len(b.data)) < end {
+		grown := make([]byte, end)
+		copy(grown, b.data)
+		b.data = grown
+	}
+	copy(b.data[off:end], p)
+	return len(p), nil
+}
+
+func (b *writerAtBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.data...)
+}
+
+func TestDownloadRanged(t *testing.T) {
+	want := bytes.Repeat([]byte("0123456789"), 1000) // 10000 bytes
+	api := &mockClientAPI{data: want, etag: `"abc123"`}
+	dst := &writerAtBuffer{}
+
+	meta, err := download(context.Background(), api, "bucket", "key", dst, objectstore.DownloadOptions{
+		Concurrency: 4,
+		PartSize:    1000,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if meta.Size != int64(len(want)) {
+		t.Errorf("Size = %d, want %d", meta.Size, len(want))
+	}
+	if meta.ETag != "abc123" {
+		t.Errorf("ETag = %q, want %q", meta.ETag, "abc123")
+	}
+	if !bytes.Equal(dst.Bytes(), want) {
+		t.Error("downloaded content did not match source")
+	}
+}
+
+func TestDownloadRetriesTransientErrors(t *testing.T) {
+	want := bytes.Repeat([]byte("x"), 2500)
+	api := &mockClientAPI{
+		data:       want,
+		failCounts: map[string]int{"bytes=0-999": 2},
+	}
+	dst := &writerAtBuffer{}
+
+	meta, err := download(context.Background(), api, "bucket", "key", dst, objectstore.DownloadOptions{
+		Concurrency: 2,
+		PartSize:    1000,
+		MaxRetries:  3,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if meta.Size != int64(len(want)) {
+		t.Fatalf("Size = %d, want %d", meta.Size, len(want))
+	}
+	if !bytes.Equal(dst.Bytes(), want) {
+		t.Error("downloaded content did not match source after retries")
+	}
+}
+
+func TestDownloadGivesUpAfterMaxRetries(t *testing.T) {
+	want := bytes.Repeat([]byte("y"), 1000)
+	api := &mockClientAPI{
+		data:       want,
+		failCounts: map[string]int{"bytes=0-999": 10},
+	}
+	dst := &writerAtBuffer{}
+
+	_, err := download(context.Background(), api, "bucket", "key", dst, objectstore.DownloadOptions{
+		Concurrency: 1,
+		PartSize:    1000,
+		MaxRetries:  1,
+	})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+// blockingBody never produces data; Read blocks until ctx (the
+// per-chunk context fetchRange derives for its stall timer) is
+// cancelled, simulating a connection that goes silent mid-chunk.
+type blockingBody struct {
+	ctx context.Context
+}
+
+func (b *blockingBody) Read(p []byte) (int, error) {
+	<-b.ctx.Done()
+	return 0, b.ctx.Err()
+}
+
+func (b *blockingBody) Close() error { return nil }
+
+type blockingAPI struct {
+	size int64
+}
+
+func (a *blockingAPI) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return &s3.HeadObjectOutput{ContentLength: &a.size}, nil
+}
+
+func (a *blockingAPI) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return &s3.GetObjectOutput{Body: &blockingBody{ctx: ctx}}, nil
+}
+
+func TestDownloadStallTimeoutCancelsInactiveChunk(t *testing.T) {
+	api := &blockingAPI{size: 100}
+	dst := &writerAtBuffer{}
+
+	_, err := download(context.Background(), api, "bucket", "key", dst, objectstore.DownloadOptions{
+		Concurrency:  1,
+		PartSize:     100,
+		MaxRetries:   0,
+		StallTimeout: 10 * time.Millisecond,
+	})
+	if err == nil || !strings.Contains(err.Error(), "stalled") {
+		t.Fatalf("expected stall error, got %v", err)
+	}
+}
+
+// slowSteadyBody trickles data out one byte at a time with a fixed
+// delay between reads, individually well under stallTimeout but
+// summing to far more than it over the whole chunk.
+type slowSteadyBody struct {
+	data  []byte
+	delay time.Duration
+}
+
+func (s *slowSteadyBody) Read(p []byte) (int, error) {
+	if len(s.data) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(s.delay)
+	n := copy(p, s.data[:1])
+	s.data = s.data[1:]
+	return n, nil
+}
+
+func (s *slowSteadyBody) Close() error { return nil }
+
+type slowSteadyAPI struct {
+	data  []byte
+	delay time.Duration
+}
+
+func (a *slowSteadyAPI) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	size := int64(len(a.data))
+	return &s3.HeadObjectOutput{ContentLength: &size}, nil
+}
+
+func (a *slowSteadyAPI) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return &s3.GetObjectOutput{Body: &slowSteadyBody{data: append([]byte(nil), a.data...), delay: a.delay}}, nil
+}
+
+func TestDownloadSurvivesSlowButSteadyReads(t *testing.T) {
+	want := []byte("0123456789")
+	api := &slowSteadyAPI{data: want, delay: 5 * time.Millisecond}
+	dst := &writerAtBuffer{}
+
+	// Per-byte delay (5ms) is well under StallTimeout (20ms), but the
+	// whole transfer (10 bytes * 5ms = 50ms) exceeds it -- a flat
+	// whole-chunk timeout would have killed this chunk.
+	_, err := download(context.Background(), api, "bucket", "key", dst, objectstore.DownloadOptions{
+		Concurrency:  1,
+		PartSize:     int64(len(want)),
+		StallTimeout: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(dst.Bytes(), want) {
+		t.Error("downloaded content did not match source")
+	}
+}
+
+func TestDownloadProgress(t *testing.T) {
+	want := bytes.Repeat([]byte("z"), 3000)
+	api := &mockClientAPI{data: want}
+	dst := &writerAtBuffer{}
+
+	var calls int32
+	var lastDownloaded int64
+	_, err := download(context.Background(), api, "bucket", "key", dst, objectstore.DownloadOptions{
+		Concurrency: 1,
+		PartSize:    1000,
+		Progress: func(downloaded, total int64) {
+			atomic.AddInt32(&calls, 1)
+			lastDownloaded = downloaded
+			if total != int64(len(want)) {
+				t.Errorf("total = %d, want %d", total, len(want))
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("progress callback called %d times, want 3", calls)
+	}
+	if lastDownloaded != int64(len(want)) {
+		t.Errorf("final downloaded = %d, want %d", lastDownloaded, len(want))
+	}
+}
@@ -0,0 +1,171 @@
+// Package s3store implements bootstrap's objectstore.Fetcher for
+// s3:// URLs, with concurrent ranged downloads and sidecar lookups.
+// It is the reference backend: the most complete, and the one new
+// backends are modeled after.
+package s3store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"bootstrap/internal/objectstore"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// getObjectAPI defines the interface for the GetObject function.
+type getObjectAPI interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// headObjectAPI defines the interface for the HeadObject function.
+type headObjectAPI interface {
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+}
+
+// clientAPI is the subset of the S3 client the backend needs.
+type clientAPI interface {
+	getObjectAPI
+	headObjectAPI
+}
+
+// Backend implements objectstore.Fetcher, objectstore.RangedFetcher,
+// and objectstore.SidecarFetcher for s3:// URLs.
+type Backend struct {
+	client clientAPI
+}
+
+// New creates an S3 backend, loading AWS credentials from the
+// environment/instance profile the usual SDK way.
+func New(ctx context.Context) (*Backend, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
+	return &Backend{client: s3.NewFromConfig(cfg)}, nil
+}
+
+func parseURL(objectURL string) (bucket, key string, err error) {
+	u, err := url.Parse(objectURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid S3 URL: %w", err)
+	}
+	if u.Scheme != "s3" {
+		return "", "", fmt.Errorf("not an S3 URL (should start with s3://)")
+	}
+	return u.Host, strings.TrimPrefix(u.Path, "/"), nil
+}
+
+// Fetch downloads the whole object as a single stream.
+func (b *Backend) Fetch(ctx context.Context, objectURL string) (io.ReadCloser, objectstore.ObjectMeta, error) {
+	bucket, key, err := parseURL(objectURL)
+	if err != nil {
+		return nil, objectstore.ObjectMeta{}, err
+	}
+
+	result, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket:       &bucket,
+		Key:          &key,
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return nil, objectstore.ObjectMeta{}, fmt.Errorf("error getting object from S3: %w", err)
+	}
+	return result.Body, metaFromGetObject(result), nil
+}
+
+// FetchRanged concurrently downloads the object into dst using ranged
+// GetObject requests, retrying individual chunks with exponential
+// backoff on transient errors.
+func (b *Backend) FetchRanged(ctx context.Context, objectURL string, dst io.WriterAt, opts objectstore.DownloadOptions) (objectstore.ObjectMeta, error) {
+	bucket, key, err := parseURL(objectURL)
+	if err != nil {
+		return objectstore.ObjectMeta{}, err
+	}
+	return download(ctx, b.client, bucket, key, dst, opts)
+}
+
+// Stat issues a HeadObject request to report the object's metadata
+// without downloading its content, used by the cache to check for a
+// hit before a full Fetch/FetchRanged.
+func (b *Backend) Stat(ctx context.Context, objectURL string) (objectstore.ObjectMeta, error) {
+	bucket, key, err := parseURL(objectURL)
+	if err != nil {
+		return objectstore.ObjectMeta{}, err
+	}
+
+	head, err := b.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       &bucket,
+		Key:          &key,
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return objectstore.ObjectMeta{}, fmt.Errorf("error getting object metadata from S3: %w", err)
+	}
+	return metaFromHeadObject(head), nil
+}
+
+// FetchSidecar downloads a key that may or may not exist, such as a
+// "<key>.sha256" or "<key>.minisig" sidecar object. It returns
+// (nil, false, nil) if the key is missing, rather than an error. These
+// sidecars are small enough to fetch with a single GetObject call.
+func (b *Backend) FetchSidecar(ctx context.Context, objectURL string) ([]byte, bool, error) {
+	bucket, key, err := parseURL(objectURL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	result, err := b.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		var nsk *types.NoSuchKey
+		var nf *types.NotFound
+		if errors.As(err, &nsk) || errors.As(err, &nf) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("error getting object from S3: %w", err)
+	}
+	defer result.Body.Close()
+
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading %s: %w", key, err)
+	}
+	return data, true, nil
+}
+
+func metaFromGetObject(result *s3.GetObjectOutput) objectstore.ObjectMeta {
+	meta := objectstore.ObjectMeta{}
+	if result.ETag != nil {
+		meta.ETag = strings.Trim(*result.ETag, `"`)
+	}
+	if result.ChecksumSHA256 != nil {
+		meta.ChecksumSHA256 = *result.ChecksumSHA256
+	}
+	if result.ContentLength != nil {
+		meta.Size = *result.ContentLength
+	}
+	return meta
+}
+
+func metaFromHeadObject(head *s3.HeadObjectOutput) objectstore.ObjectMeta {
+	meta := objectstore.ObjectMeta{}
+	if head.ETag != nil {
+		meta.ETag = strings.Trim(*head.ETag, `"`)
+	}
+	if head.ChecksumSHA256 != nil {
+		meta.ChecksumSHA256 = *head.ChecksumSHA256
+	}
+	if head.ContentLength != nil {
+		meta.Size = *head.ContentLength
+	}
+	return meta
+}
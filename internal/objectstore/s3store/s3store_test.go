@@ -0,0 +1,87 @@
+package s3store
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// stubClientAPI returns fixed GetObject/HeadObject responses, for tests
+// that don't need the ranged concurrency exercised by download_test.go.
+type stubClientAPI struct {
+	getObjectOutput *s3.GetObjectOutput
+	err             error
+}
+
+func (s *stubClientAPI) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.getObjectOutput, nil
+}
+
+func (s *stubClientAPI) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return nil, nil
+}
+
+func TestBackendFetchSidecar(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      *s3.GetObjectOutput
+		err         error
+		wantErr     bool
+		wantOK      bool
+		wantContent string
+	}{
+		{
+			name:        "sidecar exists",
+			output:      &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader("deadbeef"))},
+			wantOK:      true,
+			wantContent: "deadbeef",
+		},
+		{
+			name:   "missing sidecar is not an error",
+			err:    &types.NoSuchKey{},
+			wantOK: false,
+		},
+		{
+			name:   "not found is not an error",
+			err:    &types.NotFound{},
+			wantOK: false,
+		},
+		{
+			name:    "other S3 error is an error",
+			err:     errors.New("boom"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &Backend{client: &stubClientAPI{getObjectOutput: tt.output, err: tt.err}}
+
+			content, ok, err := b.FetchSidecar(context.Background(), "s3://bucket/key.sha256")
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && string(content) != tt.wantContent {
+				t.Errorf("content = %q, want %q", content, tt.wantContent)
+			}
+		})
+	}
+}
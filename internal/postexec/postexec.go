@@ -0,0 +1,146 @@
+// Package postexec implements the actions bootstrap can take after
+// --exec finishes: shutting down, rebooting, restarting a service, or
+// running an arbitrary follow-up command. Each action is registered
+// under a name so new ones can be added without touching main.
+package postexec
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Action is a post-exec action bootstrap can run after the downloaded
+// file finishes executing.
+type Action interface {
+	// Run performs the action. In debug mode it must print the command
+	// it would have run and return nil rather than actually running it.
+	Run(ctx context.Context, debug bool) error
+}
+
+// Factory builds an Action from the argument after "=" in a
+// --post-exec value (e.g. "NAME" in "restart-service=NAME"), or ""
+// for actions that take none.
+type Factory func(arg string) (Action, error)
+
+// registry maps a --post-exec name to its Factory. New actions are
+// added here without any changes to main.
+var registry = map[string]Factory{
+	"shutdown": func(arg string) (Action, error) {
+		if arg != "" {
+			return nil, fmt.Errorf("post-exec action %q takes no argument", "shutdown")
+		}
+		return cmdAction{unix: []string{"sudo", "shutdown", "-h", "now"}, windows: []string{"shutdown", "/s", "/t", "0"}}, nil
+	},
+	"reboot": func(arg string) (Action, error) {
+		if arg != "" {
+			return nil, fmt.Errorf("post-exec action %q takes no argument", "reboot")
+		}
+		return cmdAction{unix: []string{"sudo", "reboot"}, windows: []string{"shutdown", "/r", "/t", "0"}}, nil
+	},
+	"poweroff": func(arg string) (Action, error) {
+		if arg != "" {
+			return nil, fmt.Errorf("post-exec action %q takes no argument", "poweroff")
+		}
+		return cmdAction{unix: []string{"sudo", "poweroff"}, windows: []string{"shutdown", "/s", "/t", "0"}}, nil
+	},
+	"restart-service": func(arg string) (Action, error) {
+		if arg == "" {
+			return nil, fmt.Errorf(`post-exec action "restart-service" requires a service name: restart-service=NAME`)
+		}
+		return cmdAction{
+			unix:    []string{"systemctl", "restart", arg},
+			windows: []string{"powershell", "-Command", "Restart-Service", arg},
+		}, nil
+	},
+	"exec": func(arg string) (Action, error) {
+		if arg == "" {
+			return nil, fmt.Errorf(`post-exec action "exec" requires a command: exec=CMD`)
+		}
+		return execAction{cmd: arg}, nil
+	},
+}
+
+// Names returns the registered post-exec action names, sorted, for use
+// in usage/help text.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse resolves a --post-exec flag value ("name" or "name=arg") to its
+// Action.
+func Parse(value string) (Action, error) {
+	name, arg, _ := strings.Cut(value, "=")
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("invalid --post-exec value %q (valid actions: %s)", value, strings.Join(Names(), ", "))
+	}
+	return factory(arg)
+}
+
+// Wait sleeps for delay before a post-exec action runs, honoring ctx
+// cancellation so a Ctrl-C during the delay aborts the pending action
+// instead of blocking until it elapses.
+func Wait(ctx context.Context, delay time.Duration) error {
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cmdAction runs a fixed OS command chosen by runtime.GOOS, killing it
+// if ctx is canceled mid-run.
+type cmdAction struct {
+	unix    []string
+	windows []string
+}
+
+func (a cmdAction) Run(ctx context.Context, debug bool) error {
+	args := a.unix
+	if runtime.GOOS == "windows" {
+		args = a.windows
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	if debug {
+		fmt.Printf("Debug: Would execute command: %v\n", cmd.Args)
+		return nil
+	}
+	return cmd.Run()
+}
+
+// execAction runs an arbitrary follow-up command through the shell,
+// killing it if ctx is canceled mid-run.
+type execAction struct {
+	cmd string
+}
+
+func (a execAction) Run(ctx context.Context, debug bool) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", a.cmd)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", a.cmd)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if debug {
+		fmt.Printf("Debug: Would execute command: %v\n", cmd.Args)
+		return nil
+	}
+	return cmd.Run()
+}
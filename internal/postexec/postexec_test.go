@@ -0,0 +1,71 @@
+package postexec
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{name: "shutdown", value: "shutdown"},
+		{name: "reboot", value: "reboot"},
+		{name: "poweroff", value: "poweroff"},
+		{name: "restart-service", value: "restart-service=docker"},
+		{name: "restart-service without name", value: "restart-service", wantErr: "requires a service name"},
+		{name: "exec", value: "exec=echo hi"},
+		{name: "exec without command", value: "exec", wantErr: "requires a command"},
+		{name: "shutdown with argument", value: "shutdown=now", wantErr: "takes no argument"},
+		{name: "unknown action", value: "hibernate", wantErr: "invalid --post-exec value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, err := Parse(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse(%q) error = %v, want containing %q", tt.value, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.value, err)
+			}
+			if action == nil {
+				t.Fatalf("Parse(%q) returned a nil action", tt.value)
+			}
+		})
+	}
+}
+
+func TestActionsRunInDebugMode(t *testing.T) {
+	for _, value := range []string{"shutdown", "reboot", "poweroff", "restart-service=docker", "exec=echo hi"} {
+		action, err := Parse(value)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", value, err)
+		}
+		if err := action.Run(context.Background(), true); err != nil {
+			t.Errorf("Run(%q, debug=true) = %v, want nil", value, err)
+		}
+	}
+}
+
+func TestWaitHonorsCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := Wait(ctx, time.Minute); err != context.Canceled {
+		t.Errorf("Wait() = %v, want context.Canceled", err)
+	}
+}
+
+func TestWaitElapses(t *testing.T) {
+	if err := Wait(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("Wait() = %v, want nil", err)
+	}
+}
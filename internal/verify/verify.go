@@ -0,0 +1,84 @@
+// Package verify provides checksum and signature verification for
+// objects downloaded by bootstrap before they are executed.
+package verify
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jedisct1/go-minisign"
+)
+
+// CheckSHA256 compares a computed hex digest against an expected value,
+// which may itself be hex (e.g. a "<key>.sha256" sidecar or --sha256)
+// or base64 (S3's ChecksumSHA256 field).
+func CheckSHA256(got, want string) error {
+	want = normalizeSHA256(want)
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("sha256 mismatch: got %s, want %s", got, want)
+	}
+	return nil
+}
+
+// normalizeSHA256 accepts either a hex or base64 encoded digest and
+// returns it as lowercase hex.
+func normalizeSHA256(s string) string {
+	s = strings.TrimSpace(s)
+	// A sidecar file may contain "<digest>  <filename>" like sha256sum(1).
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		s = s[:i]
+	}
+	if decoded, err := base64.StdEncoding.DecodeString(s); err == nil && len(decoded) == sha256.Size {
+		return hex.EncodeToString(decoded)
+	}
+	return strings.ToLower(s)
+}
+
+// HashFile returns the hex-encoded SHA-256 digest of the file at path.
+// Concurrent, ranged downloads write chunks out of order, so the
+// digest can't be accumulated while streaming and must be computed in
+// a single local pass once the download is complete.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening %s for hashing: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hashing %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifySignature checks the detached Minisign signature in sigData
+// (the contents of a "<key>.minisig" sidecar) against the file at path,
+// using the given base64-encoded Minisign/Ed25519 public key.
+func VerifySignature(path, publicKey, sigData string) error {
+	pub, err := minisign.NewPublicKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("parsing verify key: %w", err)
+	}
+	sig, err := minisign.DecodeSignature(sigData)
+	if err != nil {
+		return fmt.Errorf("parsing signature: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading file for signature verification: %w", err)
+	}
+	ok, err := pub.Verify(data, sig)
+	if err != nil {
+		return fmt.Errorf("verifying signature: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("signature verification failed for %s", path)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package verify
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+func TestCheckSHA256(t *testing.T) {
+	sum := sha256.Sum256([]byte("test content"))
+	hexSum := hex.EncodeToString(sum[:])
+	b64Sum := base64.StdEncoding.EncodeToString(sum[:])
+
+	tests := []struct {
+		name    string
+		got     string
+		want    string
+		wantErr bool
+	}{
+		{name: "matching hex", got: hexSum, want: hexSum},
+		{name: "matching base64", got: hexSum, want: b64Sum},
+		{name: "sha256sum-style sidecar", got: hexSum, want: hexSum + "  bootstrap.sh\n"},
+		{name: "mismatch", got: hexSum, want: "deadbeef", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSHA256(tt.got, tt.want)
+			if tt.wantErr && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -2,10 +2,10 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
-	"net/url"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -14,67 +14,205 @@ import (
 	"strings"
 	"time"
 
-	"bootstrap/internal/s3client"
-
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"bootstrap/internal/archive"
+	"bootstrap/internal/cache"
+	"bootstrap/internal/objectstore"
+	"bootstrap/internal/objectstore/azurestore"
+	"bootstrap/internal/objectstore/filestore"
+	"bootstrap/internal/objectstore/gcsstore"
+	"bootstrap/internal/objectstore/httpstore"
+	"bootstrap/internal/objectstore/s3store"
+	"bootstrap/internal/postexec"
+	"bootstrap/internal/verify"
 )
 
-func parseS3URL(s3URL string) (bucket, key string, err error) {
-	u, err := url.Parse(s3URL)
+// newFetcher resolves objectURL's scheme to a backend's Fetcher.
+// Credentials for the chosen backend are loaded lazily, by the
+// backend's own constructor, so running against one cloud never
+// requires configuring the others. It lives here rather than in
+// objectstore so that package can stay a dependency-free leaf every
+// backend imports for the shared Fetcher/ObjectMeta types without an
+// import cycle.
+func newFetcher(ctx context.Context, objectURL string) (objectstore.Fetcher, error) {
+	u, err := objectstore.ParseURL(objectURL)
 	if err != nil {
-		return "", "", fmt.Errorf("invalid S3 URL: %w", err)
+		return nil, err
 	}
-	if u.Scheme != "s3" {
-		return "", "", fmt.Errorf("not an S3 URL (should start with s3://)")
+
+	switch {
+	case u.Scheme == "s3":
+		return s3store.New(ctx)
+	case u.Scheme == "gs":
+		return gcsstore.New(ctx)
+	case u.Scheme == "az" || strings.HasSuffix(u.Host, ".blob.core.windows.net"):
+		return azurestore.New(ctx)
+	case u.Scheme == "https" || u.Scheme == "http":
+		return httpstore.New(), nil
+	case u.Scheme == "file":
+		return filestore.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported object URL scheme %q (want s3, gs, az, https, or file)", u.Scheme)
 	}
-	return u.Host, strings.TrimPrefix(u.Path, "/"), nil
 }
 
-func executeFile(path string) error {
-	cmd := &exec.Cmd{}
+// executeFile runs the file at path. If dir is non-empty it is used as
+// the command's working directory, as when running an entrypoint
+// extracted from an archive. ctx cancellation (e.g. Ctrl-C) kills the
+// child rather than leaving bootstrap's signal handler with nothing to
+// interrupt.
+func executeFile(ctx context.Context, path, dir string) error {
+	var cmd *exec.Cmd
 
 	if runtime.GOOS == "windows" {
 		// On Windows, try to detect if it's a script that needs an interpreter
 		ext := strings.ToLower(filepath.Ext(path))
 		switch ext {
 		case ".bat", ".cmd":
-			cmd = exec.Command("cmd", "/C", path)
+			cmd = exec.CommandContext(ctx, "cmd", "/C", path)
 		case ".ps1":
-			cmd = exec.Command("powershell", "-File", path)
+			cmd = exec.CommandContext(ctx, "powershell", "-File", path)
 		case ".py":
-			cmd = exec.Command("python", path)
+			cmd = exec.CommandContext(ctx, "python", path)
 		default:
 			// For .exe and other executables
-			cmd = exec.Command(path)
+			cmd = exec.CommandContext(ctx, path)
 		}
 	} else {
 		// On Unix systems, execute directly
-		cmd = exec.Command(path)
+		cmd = exec.CommandContext(ctx, path)
 	}
 
+	cmd.Dir = dir
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
-func shutdownSystem(duration time.Duration, debug bool) error {
-	var cmd *exec.Cmd
-	time.Sleep(duration)
-	switch runtime.GOOS {
-	case "windows":
-		cmd = exec.Command("shutdown", "/s", "/t", "0")
-	default: // Linux and others
-		cmd = exec.Command("sudo", "shutdown", "-h", "now")
-	}
-	if debug {
-		fmt.Printf("Debug: Would execute command: %v\n", cmd.Args)
-		return nil
+// manifest is the shape of a manifest.json archive entrypoint: a
+// declarative alternative to a bootstrap.sh/bootstrap.ps1 script.
+type manifest struct {
+	Command []string `json:"command"`
+}
+
+// executeEntrypoint runs the entrypoint found by archive.FindEntrypoint
+// inside dir. A manifest.json entrypoint is parsed for its "command"
+// and run directly; any other entrypoint is executed as a script. ctx
+// cancellation kills the running entrypoint.
+func executeEntrypoint(ctx context.Context, path, dir string) error {
+	if filepath.Base(path) != "manifest.json" {
+		return executeFile(ctx, path, dir)
 	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading manifest.json: %w", err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("parsing manifest.json: %w", err)
+	}
+	if len(m.Command) == 0 {
+		return fmt.Errorf(`manifest.json has no "command"`)
+	}
+
+	cmd := exec.CommandContext(ctx, m.Command[0], m.Command[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
 
+// newProgressPrinter returns an objectstore.DownloadOptions.Progress
+// callback that prints a throughput/ETA line to stderr, or nil if
+// enabled is false.
+func newProgressPrinter(enabled bool) func(downloaded, total int64) {
+	if !enabled {
+		return nil
+	}
+
+	start := time.Now()
+	return func(downloaded, total int64) {
+		elapsed := time.Since(start).Seconds()
+		var rate float64
+		if elapsed > 0 {
+			rate = float64(downloaded) / elapsed
+		}
+
+		eta := "?"
+		if rate > 0 && total > downloaded {
+			eta = time.Duration(float64(total-downloaded) / rate * float64(time.Second)).Round(time.Second).String()
+		}
+
+		fmt.Fprintf(os.Stderr, "\r%s / %s  %s/s  ETA %s   ", humanBytes(downloaded), humanBytes(total), humanBytes(int64(rate)), eta)
+	}
+}
+
+// humanBytes formats n bytes using IEC binary units (KiB, MiB, ...).
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// verifyObject checks the downloaded object's integrity and, optionally,
+// its authenticity before --exec is allowed to run it. It checks, in
+// order of preference, an explicit --sha256 value, a "<url>.sha256"
+// sidecar object, and the backend's own ChecksumSHA256; if none are
+// available no checksum comparison is made. If a --verify-key is
+// supplied, a "<url>.minisig" sidecar must exist and verify against it.
+// Sidecar lookups are skipped entirely for backends that don't support
+// them.
+func verifyObject(ctx context.Context, fetcher objectstore.Fetcher, objectURL, path, gotSHA256 string, meta objectstore.ObjectMeta, wantSHA256, verifyKey string) error {
+	sidecars, _ := fetcher.(objectstore.SidecarFetcher)
+
+	switch {
+	case wantSHA256 != "":
+		// explicit flag takes precedence over anything else
+	case sidecars != nil:
+		if sidecar, ok, err := sidecars.FetchSidecar(ctx, objectURL+".sha256"); err != nil {
+			return fmt.Errorf("fetching sha256 sidecar: %w", err)
+		} else if ok {
+			wantSHA256 = string(sidecar)
+		}
+	}
+	if wantSHA256 == "" {
+		wantSHA256 = meta.ChecksumSHA256
+	}
+
+	if wantSHA256 != "" {
+		if err := verify.CheckSHA256(gotSHA256, wantSHA256); err != nil {
+			return err
+		}
+	}
+
+	if verifyKey != "" {
+		if sidecars == nil {
+			return fmt.Errorf("--verify-key set but this backend doesn't support sidecar lookups")
+		}
+		sig, ok, err := sidecars.FetchSidecar(ctx, objectURL+".minisig")
+		if err != nil {
+			return fmt.Errorf("fetching minisig sidecar: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("--verify-key set but no %s.minisig object was found", objectURL)
+		}
+		if err := verify.VerifySignature(path, verifyKey, string(sig)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func main() {
 	ctx := context.Background()
 
@@ -89,20 +227,34 @@ func main() {
 	// Create temp file path holder
 	var tmpPath string
 
-	// Goroutine for signal handling
+	// Goroutine for signal handling. It only cancels ctx and cleans up
+	// the temp file rather than exiting the process outright, so a
+	// blocking, ctx-aware stage (notably postexec.Wait's pending delay)
+	// can abort gracefully instead of being killed mid-action.
 	go func() {
 		<-sigChan
 		if tmpPath != "" {
 			os.Remove(tmpPath)
 		}
 		cancel()
-		os.Exit(1)
 	}()
 
 	saveFlag := flag.String("save", "", "Save the downloaded file to the specified path instead of a temporary location")
 	execFlag := flag.Bool("exec", false, "Execute the downloaded file")
-	postExecFlag := flag.String("post-exec", "", "Action to take after execution (only used with --exec). Valid values: shutdown")
+	postExecFlag := flag.String("post-exec", "", fmt.Sprintf("Action to take after execution (only used with --exec). Valid values: %s", strings.Join(postexec.Names(), ", ")))
+	postExecDelayFlag := flag.Duration("post-exec-delay", 20*time.Second, "Delay before the --post-exec action runs")
 	debugFlag := flag.Bool("debug", false, "Debug mode - skips post-exec actions")
+	sha256Flag := flag.String("sha256", "", "Expected SHA-256 checksum of the object (hex or base64). Falls back to a \"<key>.sha256\" sidecar object if not set")
+	verifyKeyFlag := flag.String("verify-key", "", "Base64-encoded Minisign/Ed25519 public key to verify a \"<key>.minisig\" signature against before executing")
+	concurrencyFlag := flag.Int("concurrency", objectstore.DefaultConcurrency, "Number of concurrent ranged GetObject requests (S3 only; ignored by other backends)")
+	partSizeFlag := flag.Int64("part-size", objectstore.DefaultPartSize, "Size in bytes of each ranged download chunk (S3 only; ignored by other backends)")
+	maxRetriesFlag := flag.Int("max-retries", objectstore.DefaultMaxRetries, "Number of retries for a chunk that fails with a transient error (S3 only; ignored by other backends)")
+	progressFlag := flag.Bool("progress", false, "Print download throughput and ETA to stderr")
+	extractFlag := flag.String("extract", "", "Extract the downloaded archive (.tar, .tar.gz, .tgz, .zip) into DIR")
+	defaultCacheDir, _ := cache.Dir()
+	cacheDirFlag := flag.String("cache-dir", defaultCacheDir, "Directory to cache downloaded objects in, keyed by URL and ETag")
+	cacheMaxSizeFlag := flag.Int64("cache-max-size", cache.DefaultMaxSize, "Maximum total size in bytes of --cache-dir before least-recently-used entries are evicted")
+	noCacheFlag := flag.Bool("no-cache", false, "Always download, ignoring and not populating --cache-dir")
 	flag.Parse()
 
 	if *postExecFlag != "" && !*execFlag {
@@ -110,41 +262,30 @@ func main() {
 		os.Exit(1)
 	}
 
-	if *postExecFlag != "" && *postExecFlag != "shutdown" {
-		fmt.Fprintf(os.Stderr, "Error: invalid --post-exec value. Valid values: shutdown\n")
-		os.Exit(1)
+	var postExecAction postexec.Action
+	if *postExecFlag != "" {
+		var err error
+		postExecAction, err = postexec.Parse(*postExecFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
 	args := flag.Args()
 	if len(args) != 1 {
-		fmt.Fprintf(os.Stderr, "Usage: %s [--exec] [--save path] s3://bucket/path/to/file\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s [--exec] [--save path] <s3://|gs://|az://|https://|file://>...\n", os.Args[0])
 		os.Exit(1)
 	}
+	objectURL := args[0]
+	key := objectstore.KeyName(objectURL)
 
-	bucket, key, err := parseS3URL(args[0])
+	fetcher, err := newFetcher(ctx, objectURL)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing S3 URL: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error resolving object URL: %v\n", err)
 		os.Exit(1)
 	}
 
-	cfg, err := config.LoadDefaultConfig(ctx)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Unable to load AWS config: %v\n", err)
-		os.Exit(1)
-	}
-
-	client := s3.NewFromConfig(cfg)
-
-	ctxDownload, cancelDownload := context.WithTimeout(ctx, 30*time.Second)
-	defer cancelDownload()
-
-	result, err := s3client.Download(ctxDownload, client, bucket, key)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "%v\n", err)
-		os.Exit(1)
-	}
-	defer result.Close()
-
 	var targetPath string
 	var targetFile *os.File
 
@@ -181,9 +322,66 @@ func main() {
 		}
 	}
 
-	if _, err := io.Copy(targetFile, result); err != nil {
-		fmt.Fprintf(os.Stderr, "Error copying S3 object to file: %v\n", err)
-		os.Exit(1)
+	var objCache *cache.Cache
+	if !*noCacheFlag && *cacheDirFlag != "" {
+		objCache, err = cache.New(*cacheDirFlag, *cacheMaxSizeFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: disabling cache: %v\n", err)
+			objCache = nil
+		}
+	}
+
+	downloadOpts := objectstore.DownloadOptions{
+		Concurrency:  *concurrencyFlag,
+		PartSize:     *partSizeFlag,
+		MaxRetries:   *maxRetriesFlag,
+		StallTimeout: objectstore.DefaultStallTimeout,
+		Progress:     newProgressPrinter(*progressFlag),
+	}
+
+	var meta, statMeta objectstore.ObjectMeta
+	cacheHit := false
+	if objCache != nil {
+		if statter, ok := fetcher.(objectstore.StatFetcher); ok {
+			if statMeta, err = statter.Stat(ctx, objectURL); err == nil {
+				if cachedPath, ok := objCache.Lookup(objectURL, statMeta); ok {
+					if cached, err := os.Open(cachedPath); err == nil {
+						_, copyErr := io.Copy(targetFile, cached)
+						cached.Close()
+						cacheHit = copyErr == nil
+					}
+					if !cacheHit {
+						// A partial copy may have left bytes in
+						// targetFile; rewind so the fallback download
+						// below starts from a clean slate.
+						targetFile.Truncate(0)
+						targetFile.Seek(0, io.SeekStart)
+					}
+				}
+				if cacheHit {
+					meta = statMeta
+				}
+			}
+		}
+	}
+
+	if !cacheHit {
+		if ranged, ok := fetcher.(objectstore.RangedFetcher); ok {
+			meta, err = ranged.FetchRanged(ctx, objectURL, targetFile, downloadOpts)
+		} else {
+			var body io.ReadCloser
+			body, meta, err = fetcher.Fetch(ctx, objectURL)
+			if err == nil {
+				_, err = io.Copy(targetFile, body)
+				body.Close()
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		fmt.Printf("Using cached copy of %s\n", objectURL)
 	}
 
 	// Make file executable on Unix systems
@@ -196,17 +394,83 @@ func main() {
 
 	targetFile.Close()
 
+	if objCache != nil && !cacheHit {
+		// Some backends (GCS) report no ETag from a Fetch; key the entry
+		// by the ETag Stat reported instead.
+		if meta.ETag == "" {
+			meta.ETag = statMeta.ETag
+		}
+		if err := objCache.Put(objectURL, meta, targetPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to cache %s: %v\n", objectURL, err)
+		}
+	}
+
+	// Verify the downloaded object before anything derived from it
+	// (an extracted archive, an executed entrypoint) touches disk or
+	// runs — otherwise an unverified archive's contents would already
+	// be sitting in --extract's directory by the time verification
+	// failed, defeating the point of verifying at all. This runs for
+	// --extract alone, not just --exec, since extraction is what
+	// actually writes the untrusted content to disk.
+	if *execFlag || *extractFlag != "" {
+		gotSHA256, err := verify.HashFile(targetPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(1)
+		}
+
+		if err := verifyObject(ctx, fetcher, objectURL, targetPath, gotSHA256, meta, *sha256Flag, *verifyKeyFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Refusing to process %s: %v\n", targetPath, err)
+			os.Exit(1)
+		}
+	}
+
+	if *extractFlag != "" {
+		format := archive.DetectFormat(key)
+		if format == archive.FormatNone {
+			fmt.Fprintf(os.Stderr, "Error: %s is not a supported archive (.tar, .tar.gz, .tgz, .zip)\n", key)
+			os.Exit(1)
+		}
+		if err := archive.Extract(targetPath, format, *extractFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "Error extracting archive: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	if *execFlag {
+		execPath, execDir := targetPath, ""
+		if *extractFlag != "" {
+			entry, err := archive.FindEntrypoint(*extractFlag)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error looking for archive entrypoint: %v\n", err)
+				os.Exit(1)
+			}
+			if entry == "" {
+				fmt.Fprintf(os.Stderr, "Error: no bootstrap.sh/bootstrap.ps1/manifest.json entrypoint found in %s\n", *extractFlag)
+				os.Exit(1)
+			}
+			if runtime.GOOS != "windows" {
+				if err := os.Chmod(entry, 0755); err != nil {
+					fmt.Fprintf(os.Stderr, "Error making entrypoint executable: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			execPath, execDir = entry, *extractFlag
+		}
+
 		var exitStatus int
-		if err := executeFile(targetPath); err != nil {
+		if err := executeEntrypoint(ctx, execPath, execDir); err != nil {
 			fmt.Fprintf(os.Stderr, "Error executing file: %v\n", err)
 			exitStatus = 1
 		}
 
-		if *postExecFlag == "shutdown" {
-			fmt.Println("System will shutdown in 20 seconds...")
-			if err := shutdownSystem(time.Duration(20)*time.Second, *debugFlag); err != nil {
-				fmt.Fprintf(os.Stderr, "Error initiating shutdown: %v\n", err)
+		if postExecAction != nil {
+			fmt.Printf("Running post-exec action %q in %s...\n", *postExecFlag, *postExecDelayFlag)
+			if err := postexec.Wait(ctx, *postExecDelayFlag); err != nil {
+				fmt.Fprintf(os.Stderr, "Post-exec action aborted: %v\n", err)
+				exitStatus = 1
+			} else if err := postExecAction.Run(ctx, *debugFlag); err != nil {
+				fmt.Fprintf(os.Stderr, "Error running post-exec action: %v\n", err)
 				exitStatus = 1
 			}
 		}
@@ -2,188 +2,63 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
-	"time"
-
-	"bootstrap/internal/s3client"
-
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
-type mockS3Client struct {
-	getObjectOutput *s3.GetObjectOutput
-	err             error
-}
-
-func (m *mockS3Client) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
-	if m.err != nil {
-		return nil, m.err
-	}
-	return m.getObjectOutput, nil
-}
-
-func TestDownloadFromS3(t *testing.T) {
-	testCases := []struct {
-		name        string
-		bucket      string
-		key         string
-		mockOutput  *s3.GetObjectOutput
-		mockErr     error
-		wantErr     bool
-		wantContent string
-	}{
-		{
-			name:   "successful download",
-			bucket: "test-bucket",
-			key:    "test-key",
-			mockOutput: &s3.GetObjectOutput{
-				Body: io.NopCloser(strings.NewReader("test content")),
-			},
-			wantContent: "test content",
-		},
-		{
-			name:    "s3 error",
-			bucket:  "test-bucket",
-			key:     "test-key",
-			mockErr: &types.NoSuchKey{},
-			wantErr: true,
-		},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			mockSvc := &mockS3Client{
-				getObjectOutput: tc.mockOutput,
-				err:             tc.mockErr,
-			}
-
-			result, err := s3client.Download(context.Background(), mockSvc, tc.bucket, tc.key)
-
-			if tc.wantErr {
-				if err == nil {
-					t.Fatal("expected error, got none")
-				}
-				return
-			}
-			if err != nil {
-				t.Fatalf("unexpected error: %v", err)
-			}
-
-			content, err := io.ReadAll(result)
-			if err != nil {
-				t.Fatalf("failed to read content: %v", err)
-			}
-			if string(content) != tc.wantContent {
-				t.Errorf("got content %q, want %q", string(content), tc.wantContent)
-			}
-		})
-	}
-}
-
-func TestParseS3URL(t *testing.T) {
+func TestNewFetcherDispatchesByScheme(t *testing.T) {
 	tests := []struct {
-		name        string
-		url         string
-		wantBucket  string
-		wantKey     string
-		wantErr     bool
-		errContains string
+		name     string
+		url      string
+		wantType string
+		wantErr  string
 	}{
-		{
-			name:       "valid S3 URL",
-			url:        "s3://my-bucket/path/to/file.txt",
-			wantBucket: "my-bucket",
-			wantKey:    "path/to/file.txt",
-			wantErr:    false,
-		},
-		{
-			name:        "invalid scheme",
-			url:         "http://my-bucket/file.txt",
-			wantErr:     true,
-			errContains: "not an S3 URL",
-		},
-		{
-			name:        "invalid URL format",
-			url:         "not-a-url",
-			wantErr:     true,
-			errContains: "not an S3 URL",
-		},
+		{name: "https", url: "https://example.com/bundle.tar.gz", wantType: "*httpstore.Backend"},
+		{name: "http", url: "http://example.com/bundle.tar.gz", wantType: "*httpstore.Backend"},
+		{name: "file", url: "file:///tmp/bundle.tar.gz", wantType: "*filestore.Backend"},
+		{name: "unsupported scheme", url: "ftp://example.com/bundle.tar.gz", wantErr: `unsupported object URL scheme "ftp"`},
+		{name: "invalid URL", url: "://bad", wantErr: "invalid object URL"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			bucket, key, err := parseS3URL(tt.url)
-			if tt.wantErr {
-				if err == nil {
-					t.Error("expected error, got nil")
-				}
-				if !strings.Contains(err.Error(), tt.errContains) {
-					t.Errorf("error %q should contain %q", err.Error(), tt.errContains)
+			got, err := newFetcher(context.Background(), tt.url)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("newFetcher(%q) error = %v, want containing %q", tt.url, err, tt.wantErr)
 				}
 				return
 			}
 			if err != nil {
-				t.Errorf("unexpected error: %v", err)
+				t.Fatalf("newFetcher(%q) unexpected error: %v", tt.url, err)
 			}
-			if bucket != tt.wantBucket {
-				t.Errorf("bucket = %q, want %q", bucket, tt.wantBucket)
-			}
-			if key != tt.wantKey {
-				t.Errorf("key = %q, want %q", key, tt.wantKey)
+			if gotType := fmt.Sprintf("%T", got); gotType != tt.wantType {
+				t.Errorf("newFetcher(%q) = %s, want %s", tt.url, gotType, tt.wantType)
 			}
 		})
 	}
 }
 
 func TestExecuteFile(t *testing.T) {
-	err := executeFile("nonexistent-file")
+	err := executeFile(context.Background(), "nonexistent-file", "")
 	if err == nil {
 		t.Error("expected error for nonexistent file, got nil")
 	}
 }
 
-func TestShutdownSystemDebug(t *testing.T) {
-	// capture stdout
-	r, w, _ := os.Pipe()
-	origStdout := os.Stdout
-	os.Stdout = w
-
-	done := make(chan struct{})
-	go func() {
-		// should return quickly and not actually shutdown
-		err := shutdownSystem(10*time.Millisecond, true)
-		if err != nil {
-			t.Errorf("expected nil error in debug mode, got %v", err)
-		}
-		w.Close()
-		done <- struct{}{}
-	}()
-
-	// read output
-	var out strings.Builder
-	io.Copy(&out, r)
-	<-done
-	os.Stdout = origStdout
-
-	if !strings.Contains(out.String(), "Debug: Would execute command:") {
-		t.Errorf("expected debug output, got: %q", out.String())
-	}
-}
-
 func TestSaveFlag(t *testing.T) {
 	// Create a temporary directory for our tests
 	tempDir := t.TempDir()
 
 	testCases := []struct {
-		name           string
-		savePath       string
-		content        string
+		name            string
+		savePath        string
+		content         string
 		expectDirCreate bool
 	}{
 		{
@@ -192,9 +67,9 @@ func TestSaveFlag(t *testing.T) {
 			content:  "test content",
 		},
 		{
-			name:           "save to nested path",
-			savePath:       tempDir + "/nested/dir/test-file.txt",
-			content:        "nested content",
+			name:            "save to nested path",
+			savePath:        tempDir + "/nested/dir/test-file.txt",
+			content:         "nested content",
 			expectDirCreate: true,
 		},
 	}
@@ -255,55 +130,3 @@ func TestSaveFlag(t *testing.T) {
 		})
 	}
 }
-
-func TestSaveFlagWithMockS3(t *testing.T) {
-	tempDir := t.TempDir()
-	savePath := tempDir + "/downloaded-file.txt"
-	expectedContent := "content from S3"
-
-	// Create a mock S3 client
-	mockSvc := &mockS3Client{
-		getObjectOutput: &s3.GetObjectOutput{
-			Body: io.NopCloser(strings.NewReader(expectedContent)),
-		},
-	}
-
-	// Simulate the download and save process
-	result, err := s3client.Download(context.Background(), mockSvc, "test-bucket", "test-key")
-	if err != nil {
-		t.Fatalf("Download failed: %v", err)
-	}
-	defer result.Close()
-
-	// Create parent directories
-	dir := filepath.Dir(savePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		t.Fatalf("Error creating directories: %v", err)
-	}
-
-	// Create and write to file
-	targetFile, err := os.Create(savePath)
-	if err != nil {
-		t.Fatalf("Error creating file: %v", err)
-	}
-
-	if _, err := io.Copy(targetFile, result); err != nil {
-		t.Fatalf("Error copying content: %v", err)
-	}
-
-	if runtime.GOOS != "windows" {
-		if err := targetFile.Chmod(0755); err != nil {
-			t.Fatalf("Error setting permissions: %v", err)
-		}
-	}
-	targetFile.Close()
-
-	// Verify the file was saved correctly
-	savedContent, err := os.ReadFile(savePath)
-	if err != nil {
-		t.Fatalf("Error reading saved file: %v", err)
-	}
-	if string(savedContent) != expectedContent {
-		t.Errorf("Content mismatch: got %q, want %q", savedContent, expectedContent)
-	}
-}